@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ContextOption configures a ContextualLogger constructed via
+// NewContextualLogger.
+type ContextOption interface {
+	apply(*ContextualLogger)
+}
+
+type contextOptionFunc func(*ContextualLogger)
+
+func (f contextOptionFunc) apply(log *ContextualLogger) { f(log) }
+
+// WithCallerFunction enables per-entry annotation of the calling package and
+// function (as "pkg" and "func" fields) and the call-site line (as "line"),
+// derived via runtime.Caller. "pkg" is the short package name (e.g. "zap"),
+// matching RegisterPackage's auto-detected name, not the full import path.
+//
+// This is distinct from the base Logger's AddCaller, which records a single
+// file:line string; WithCallerFunction exposes package and function
+// separately so log backends can filter or group on them directly.
+func WithCallerFunction() ContextOption {
+	return contextOptionFunc(func(log *ContextualLogger) {
+		log.callerFunction = true
+	})
+}
+
+// NewContextualLogger wraps base in a ContextualLogger, applying any
+// ContextOptions given.
+func NewContextualLogger(base *Logger, opts ...ContextOption) *ContextualLogger {
+	log := &ContextualLogger{base: base}
+	for _, opt := range opts {
+		opt.apply(log)
+	}
+	return log
+}
+
+// callerFuncInfo is the cached result of resolving a PC to a package and
+// function name.
+type callerFuncInfo struct {
+	pkg string
+	fn  string
+}
+
+// callerFuncCache memoizes callerFuncInfo by PC, since runtime.FuncForPC and
+// the subsequent name-splitting are too costly to redo on every log call.
+var callerFuncCache sync.Map // map[uintptr]callerFuncInfo
+
+// callerFuncFields returns the pkg/func/line fields for the frame skip
+// levels above callerFuncFields itself, or nil if the frame can't be
+// resolved.
+func callerFuncFields(skip int) []Field {
+	pc, _, line, ok := runtime.Caller(skip)
+	if !ok {
+		return nil
+	}
+
+	info, ok := callerFuncCache.Load(pc)
+	if !ok {
+		pkg, fn := "unknown", "unknown"
+		if f := runtime.FuncForPC(pc); f != nil {
+			pkg, fn = splitFuncName(f.Name())
+		}
+		info, _ = callerFuncCache.LoadOrStore(pc, callerFuncInfo{pkg: pkg, fn: fn})
+	}
+
+	ci := info.(callerFuncInfo)
+	return []Field{String("pkg", ci.pkg), String("func", ci.fn), Int("line", line)}
+}
+
+// splitFuncName splits the dotted name runtime.Func.Name returns (e.g.
+// "go.uber.org/zap.(*Logger).Info" or "main.main") into its short package
+// name and function parts.
+func splitFuncName(full string) (pkg, fn string) {
+	base := full
+	if i := strings.LastIndex(full, "/"); i >= 0 {
+		base = full[i+1:]
+	}
+
+	if i := strings.Index(base, "."); i >= 0 {
+		return base[:i], base[i+1:]
+	}
+	return base, base
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSplitFuncName(t *testing.T) {
+	tests := []struct {
+		full    string
+		wantPkg string
+		wantFn  string
+	}{
+		{"go.uber.org/zap.(*Logger).Info", "zap", "(*Logger).Info"},
+		{"main.main", "main", "main"},
+		{"noDotAtAll", "noDotAtAll", "noDotAtAll"},
+	}
+	for _, tt := range tests {
+		pkg, fn := splitFuncName(tt.full)
+		assert.Equal(t, tt.wantPkg, pkg, tt.full)
+		assert.Equal(t, tt.wantFn, fn, tt.full)
+	}
+}
+
+func TestContextualLogger_WithCallerFunction(t *testing.T) {
+	core, logs := observer.New(DebugLevel)
+	log := NewContextualLogger(New(core), WithCallerFunction())
+
+	logFromHere(log)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+
+	fieldsByKey := make(map[string]string, len(entry.Context))
+	for _, f := range entry.Context {
+		fieldsByKey[f.Key] = f.String
+	}
+	assert.Equal(t, "zap", fieldsByKey["pkg"])
+	assert.Equal(t, "logFromHere", fieldsByKey["func"])
+
+	var sawLine bool
+	for _, f := range entry.Context {
+		if f.Key == "line" {
+			sawLine = true
+			assert.Greater(t, f.Integer, int64(0))
+		}
+	}
+	assert.True(t, sawLine, "expected a line field")
+}
+
+func TestContextualLogger_WithoutCallerFunction(t *testing.T) {
+	core, logs := observer.New(DebugLevel)
+	log := NewContextualLogger(New(core))
+
+	log.Info(context.Background(), "no annotation")
+
+	require.Equal(t, 1, logs.Len())
+	for _, f := range logs.All()[0].Context {
+		assert.NotEqual(t, "pkg", f.Key)
+		assert.NotEqual(t, "func", f.Key)
+	}
+}
+
+// logFromHere exists purely so TestContextualLogger_WithCallerFunction has a
+// known, stable function name to assert against.
+func logFromHere(log *ContextualLogger) {
+	log.Info(context.Background(), "hello")
+}
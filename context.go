@@ -0,0 +1,118 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"sync"
+)
+
+// fieldsContextKey is the unexported context.Context key under which
+// AppendFieldsToContext stores its field slice.
+type fieldsContextKey struct{}
+
+// AppendFieldsToContext returns a copy of ctx carrying fields in addition to
+// any fields already attached by a previous call. The original ctx, and any
+// fields already stored on it, are left untouched, so sibling scopes derived
+// from the same parent never observe each other's fields.
+//
+// This lets middleware attach request-scoped metadata (request_id, trace_id,
+// user_id, ...) once near the edge of a request and have every ContextualLogger
+// call made with that context automatically include it, without threading a
+// *Logger through the call stack.
+func AppendFieldsToContext(ctx context.Context, fields ...Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// FieldsFromContext returns a copy of the fields previously attached to ctx
+// via AppendFieldsToContext, or nil if none were attached. Because the
+// returned slice is a copy, the caller is free to mutate or append to it
+// without racing with other goroutines reading fields from the same ctx.
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey{}).([]Field)
+	if len(fields) == 0 {
+		return nil
+	}
+	cp := make([]Field, len(fields))
+	copy(cp, fields)
+	return cp
+}
+
+// contextKeysMu guards contextKeys.
+var contextKeysMu sync.RWMutex
+
+// contextKeys lists the context.Context keys DetachContext copies into a
+// detached context. It starts with the keys zap uses internally and grows as
+// callers register their own via RegisterContextKey.
+var contextKeys = []any{
+	fieldsContextKey{},
+	levelContextKey{},
+}
+
+// RegisterContextKey opts an additional context.Context key into the set
+// DetachContext preserves. Use it for values zap doesn't know about itself,
+// such as an OpenTelemetry span context, that should still survive a detach.
+func RegisterContextKey(key any) {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+	contextKeys = append(contextKeys, key)
+}
+
+// DetachContext returns a new context.Context rooted in context.Background --
+// carrying no deadline, cancellation, or Done channel of its own -- but
+// preserving the values stored under every key zap controls (the fields
+// added via AppendFieldsToContext, any level override from WithContextLevel,
+// and any key opted in via RegisterContextKey).
+//
+// This is for spawning background work from a request handler: the
+// background goroutine keeps logging with the request's request_id/trace_id
+// fields, but isn't cancelled when the request's own context is.
+func DetachContext(parent context.Context) context.Context {
+	ctx := context.Background()
+	if parent == nil {
+		return ctx
+	}
+
+	contextKeysMu.RLock()
+	keys := make([]any, len(contextKeys))
+	copy(keys, contextKeys)
+	contextKeysMu.RUnlock()
+
+	for _, key := range keys {
+		if v := parent.Value(key); v != nil {
+			ctx = context.WithValue(ctx, key, v)
+		}
+	}
+	return ctx
+}
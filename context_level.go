@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// levelContextKey is the unexported context.Context key under which
+// WithContextLevel stores its override level.
+type levelContextKey struct{}
+
+// WithContextLevel returns a copy of ctx that forces ContextualLogger to
+// treat lvl (and anything more severe) as enabled, regardless of how the
+// logger's underlying core is configured. This allows a single request to be
+// traced at DebugLevel while the rest of the process keeps logging at, say,
+// InfoLevel.
+//
+// The override only ever lowers the effective level for calls made with
+// ctx; it cannot silence a level the core would otherwise write.
+func WithContextLevel(ctx context.Context, lvl Level) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, levelContextKey{}, lvl)
+}
+
+// contextLevel reports the override level attached to ctx via
+// WithContextLevel, if any.
+func contextLevel(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	lvl, ok := ctx.Value(levelContextKey{}).(Level)
+	return lvl, ok
+}
+
+// forceEnabledCore wraps a Core and reports every level as enabled,
+// regardless of what the wrapped Core's own Enabled/Check would decide. This
+// is what lets a per-context level override bypass both the core's
+// configured level and any sampling it applies at Check time.
+type forceEnabledCore struct {
+	zapcore.Core
+}
+
+func (c forceEnabledCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c forceEnabledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+// check is like log.base.check, but honors a per-context level override
+// attached via WithContextLevel: if ctx carries an override at or below
+// lvl, the base core's level gate (and any sampling) is bypassed for this
+// entry only.
+func (log *ContextualLogger) check(ctx context.Context, lvl Level, msg string) *CheckedEntry {
+	if min, ok := contextLevel(ctx); ok && min <= lvl {
+		return log.checkWithMinLevel(lvl, msg)
+	}
+	return log.base.check(lvl, msg)
+}
+
+// checkWithMinLevel builds a CheckedEntry for lvl as if the base logger's
+// core always reported itself enabled, without mutating the base logger or
+// any level shared with other callers.
+func (log *ContextualLogger) checkWithMinLevel(lvl Level, msg string) *CheckedEntry {
+	forced := log.base.WithOptions(WrapCore(func(core zapcore.Core) zapcore.Core {
+		return forceEnabledCore{Core: core}
+	}))
+	return forced.check(lvl, msg)
+}
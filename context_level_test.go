@@ -0,0 +1,79 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestContextualLogger_ContextLevelOverride(t *testing.T) {
+	core, logs := observer.New(InfoLevel)
+	log := &ContextualLogger{base: New(core)}
+
+	log.Debug(context.Background(), "no override")
+	assert.Equal(t, 0, logs.Len(), "Debug below the core's level should be dropped without an override")
+
+	ctx := WithContextLevel(context.Background(), DebugLevel)
+	log.Debug(ctx, "with override")
+	require.Equal(t, 1, logs.Len(), "Debug should be written once an override enables it")
+	assert.Equal(t, "with override", logs.All()[0].Message)
+}
+
+func TestContextualLogger_ContextLevelOverride_AboveCallSite(t *testing.T) {
+	core, logs := observer.New(InfoLevel)
+	log := &ContextualLogger{base: New(core)}
+
+	// An override of WarnLevel shouldn't enable Debug calls; it only forces
+	// levels at or above the override floor.
+	ctx := WithContextLevel(context.Background(), WarnLevel)
+	log.Debug(ctx, "still too low")
+	assert.Equal(t, 0, logs.Len())
+
+	log.Warn(ctx, "at the override floor")
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestContextualLogger_ContextLevelOverride_BypassesSampler(t *testing.T) {
+	inner, logs := observer.New(DebugLevel)
+	sampled := zapcore.NewSamplerWithOptions(inner, time.Minute, 1, 0)
+	log := &ContextualLogger{base: New(sampled)}
+
+	for i := 0; i < 5; i++ {
+		log.Debug(context.Background(), "sampled")
+	}
+	assert.Equal(t, 1, logs.Len(), "the sampler should have dropped all but the first entry in this tick")
+
+	logs.TakeAll()
+
+	ctx := WithContextLevel(context.Background(), DebugLevel)
+	for i := 0; i < 5; i++ {
+		log.Debug(ctx, "override")
+	}
+	assert.Equal(t, 5, logs.Len(), "a context-level override should bypass the sampler entirely")
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendFieldsToContext_SiblingIsolation(t *testing.T) {
+	parent := AppendFieldsToContext(context.Background(), String("request_id", "abc"))
+
+	child1 := AppendFieldsToContext(parent, String("handler", "one"))
+	child2 := AppendFieldsToContext(parent, String("handler", "two"))
+
+	assert.Equal(t, []Field{String("request_id", "abc")}, FieldsFromContext(parent),
+		"parent fields must be unaffected by either child")
+	assert.Equal(t, []Field{String("request_id", "abc"), String("handler", "one")}, FieldsFromContext(child1))
+	assert.Equal(t, []Field{String("request_id", "abc"), String("handler", "two")}, FieldsFromContext(child2))
+}
+
+func TestAppendFieldsToContext_NilContext(t *testing.T) {
+	var ctx context.Context
+	assert.NotPanics(t, func() {
+		ctx = AppendFieldsToContext(ctx, String("k", "v"))
+	})
+	assert.Equal(t, []Field{String("k", "v")}, FieldsFromContext(ctx))
+}
+
+func TestAppendFieldsToContext_NoFieldsReturnsSameContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), struct{}{}, 1)
+	assert.Equal(t, ctx, AppendFieldsToContext(ctx))
+}
+
+func TestFieldsFromContext_ReturnedSliceIsACopy(t *testing.T) {
+	ctx := AppendFieldsToContext(context.Background(), String("k", "original"))
+
+	fields := FieldsFromContext(ctx)
+	fields[0] = String("k", "mutated")
+
+	assert.Equal(t, []Field{String("k", "original")}, FieldsFromContext(ctx),
+		"mutating a previously returned slice must not affect later reads from the same ctx")
+}
+
+func TestFieldsFromContext_Empty(t *testing.T) {
+	assert.Nil(t, FieldsFromContext(context.Background()))
+	assert.Nil(t, FieldsFromContext(nil))
+}
@@ -29,55 +29,75 @@ import (
 // context.Context.
 //
 // ContextualLogger requires EncoderConfig.EncodeContext handler to be set.
+//
+// Fields attached to a context.Context via AppendFieldsToContext are merged
+// into every entry automatically, in addition to (and before) EncodeContext,
+// so the two mechanisms compose.
 type ContextualLogger struct {
 	base *Logger
+
+	// callerFunction enables per-entry pkg/func/line annotation; see
+	// WithCallerFunction.
+	callerFunction bool
 }
 
 // Named adds a new path segment to the logger's name. Segments are joined by
 // periods. By default, Loggers are unnamed.
 func (log *ContextualLogger) Named(s string) *ContextualLogger {
-	return &ContextualLogger{log.base.Named(s)}
+	return &ContextualLogger{base: log.base.Named(s), callerFunction: log.callerFunction}
 }
 
 // With creates a child logger and adds structured context to it. Fields added
 // to the child don't affect the parent, and vice versa.
 func (log *ContextualLogger) With(fields ...Field) *ContextualLogger {
-	return &ContextualLogger{log.base.With(fields...)}
+	return &ContextualLogger{base: log.base.With(fields...), callerFunction: log.callerFunction}
 }
 
 // Debug logs a message at DebugLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (log *ContextualLogger) Debug(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(DebugLevel, msg); ce != nil {
+	if ce := log.check(ctx, DebugLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
 // Info logs a message at InfoLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (log *ContextualLogger) Info(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(InfoLevel, msg); ce != nil {
+	if ce := log.check(ctx, InfoLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
 // Warn logs a message at WarnLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (log *ContextualLogger) Warn(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(WarnLevel, msg); ce != nil {
+	if ce := log.check(ctx, WarnLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
 // Error logs a message at ErrorLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (log *ContextualLogger) Error(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(ErrorLevel, msg); ce != nil {
+	if ce := log.check(ctx, ErrorLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
@@ -88,9 +108,12 @@ func (log *ContextualLogger) Error(ctx context.Context, msg string, fields ...Fi
 // "development panic"). This is useful for catching errors that are
 // recoverable, but shouldn't ever happen.
 func (log *ContextualLogger) DPanic(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(DPanicLevel, msg); ce != nil {
+	if ce := log.check(ctx, DPanicLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
@@ -99,9 +122,12 @@ func (log *ContextualLogger) DPanic(ctx context.Context, msg string, fields ...F
 //
 // The logger then panics, even if logging at PanicLevel is disabled.
 func (log *ContextualLogger) Panic(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(PanicLevel, msg); ce != nil {
+	if ce := log.check(ctx, PanicLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
@@ -111,9 +137,12 @@ func (log *ContextualLogger) Panic(ctx context.Context, msg string, fields ...Fi
 // The logger then calls os.Exit(1), even if logging at FatalLevel is
 // disabled.
 func (log *ContextualLogger) Fatal(ctx context.Context, msg string, fields ...Field) {
-	if ce := log.base.check(FatalLevel, msg); ce != nil {
+	if ce := log.check(ctx, FatalLevel, msg); ce != nil {
 		ce.Context = ctx
-		ce.Write(fields...)
+		if log.callerFunction {
+			fields = append(callerFuncFields(2), fields...)
+		}
+		ce.Write(append(FieldsFromContext(ctx), fields...)...)
 	}
 }
 
@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetachContext(t *testing.T) {
+	defer resetContextKeys()
+
+	type customKey struct{}
+	RegisterContextKey(customKey{})
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	parent = AppendFieldsToContext(parent, String("request_id", "abc"))
+	parent = WithContextLevel(parent, DebugLevel)
+	parent = context.WithValue(parent, customKey{}, "custom-value")
+
+	detached := DetachContext(parent)
+
+	_, hasDeadline := detached.Deadline()
+	assert.False(t, hasDeadline, "detached context must not inherit the parent's deadline")
+	assert.Nil(t, detached.Done(), "detached context must not inherit the parent's cancellation")
+	assert.NoError(t, detached.Err())
+
+	assert.Equal(t, []Field{String("request_id", "abc")}, FieldsFromContext(detached))
+	lvl, ok := contextLevel(detached)
+	require.True(t, ok)
+	assert.Equal(t, DebugLevel, lvl)
+	assert.Equal(t, "custom-value", detached.Value(customKey{}))
+}
+
+func TestDetachContext_NilParent(t *testing.T) {
+	detached := DetachContext(nil)
+	require.NotNil(t, detached)
+	assert.Nil(t, detached.Done())
+}
+
+// resetContextKeys restores contextKeys to its default set after a test
+// registers extra keys via RegisterContextKey.
+func resetContextKeys() {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+	contextKeys = []any{fieldsContextKey{}, levelContextKey{}}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	registryMu     sync.RWMutex
+	packageLoggers = make(map[string]*ContextualLogger)
+	packageLevels  = make(map[string]AtomicLevel)
+)
+
+// levelGatedCore wraps a Core with an independently adjustable level, on top
+// of whatever level the wrapped Core already enforces. It's how RegisterPackage
+// gives each registered package its own runtime-adjustable verbosity without
+// rebuilding the logger on every change.
+type levelGatedCore struct {
+	zapcore.Core
+	level AtomicLevel
+}
+
+func (c levelGatedCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+func (c levelGatedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// With must be overridden explicitly: without it, method promotion from the
+// embedded Core would return a bare core.With(fields) that has dropped the
+// level gate, so any logger derived via With (including through
+// ContextualLogger.With) would stop honoring SetPackageLevel/SetAllLevels.
+func (c levelGatedCore) With(fields []Field) zapcore.Core {
+	return levelGatedCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// RegisterPackage creates a ContextualLogger for name, registers it so its
+// level can be adjusted later via SetPackageLevel or SetAllLevels, and
+// returns it. If name is empty, the caller's package is detected via
+// runtime.Caller.
+//
+// The returned logger's core is wrapped in its own AtomicLevel, so flipping
+// that package's level takes effect immediately, without rebuilding the
+// logger or touching any other registered package.
+func RegisterPackage(name string, opts ...Option) (*ContextualLogger, error) {
+	if name == "" {
+		name = callerPackage(1)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := packageLoggers[name]; ok {
+		return nil, fmt.Errorf("zap: package %q is already registered", name)
+	}
+
+	atom := NewAtomicLevel()
+	cfg := NewProductionConfig()
+	cfg.Level = atom
+	base, err := cfg.Build(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("zap: building logger for package %q: %w", name, err)
+	}
+
+	base = base.Named(name).WithOptions(WrapCore(func(core zapcore.Core) zapcore.Core {
+		return levelGatedCore{Core: core, level: atom}
+	}))
+
+	logger := &ContextualLogger{base: base}
+	packageLoggers[name] = logger
+	packageLevels[name] = atom
+	return logger, nil
+}
+
+// SetPackageLevel adjusts the level of the package previously registered
+// under name. It returns an error if no such package was registered.
+func SetPackageLevel(name string, lvl Level) error {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	atom, ok := packageLevels[name]
+	if !ok {
+		return fmt.Errorf("zap: package %q is not registered", name)
+	}
+	atom.SetLevel(lvl)
+	return nil
+}
+
+// SetAllLevels adjusts the level of every registered package to lvl.
+func SetAllLevels(lvl Level) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, atom := range packageLevels {
+		atom.SetLevel(lvl)
+	}
+}
+
+// PackageLevels returns the current level of every registered package, keyed
+// by the name it was registered under.
+func PackageLevels() map[string]Level {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	levels := make(map[string]Level, len(packageLevels))
+	for name, atom := range packageLevels {
+		levels[name] = atom.Level()
+	}
+	return levels
+}
+
+// callerPackage derives a package name for RegisterPackage callers that pass
+// an empty name, by walking skip+1 frames up the stack.
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+
+	pkg, _ := splitFuncName(fn.Name())
+	return pkg
+}
+
+// levelsHandler serves the runtime package-level API exposed by LevelsHandler.
+type levelsHandler struct{}
+
+// LevelsHandler returns an http.Handler that lets operators inspect and
+// adjust registered packages' levels at runtime:
+//
+//	GET  /         lists every registered package and its current level
+//	PUT  /         adjusts a single package's level
+//	POST /         same as PUT
+//
+// PUT/POST requests take a JSON body of the form {"name": "...", "level": "debug"}.
+func LevelsHandler() http.Handler {
+	return levelsHandler{}
+}
+
+func (levelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeLevelsJSON(w, http.StatusOK, PackageLevels())
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Name  string `json:"name"`
+			Level Level  `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := SetPackageLevel(req.Name, req.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeLevelsJSON(w, http.StatusOK, PackageLevels())
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeLevelsJSON(w http.ResponseWriter, status int, levels map[string]Level) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(levels)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRegisterPackage(t *testing.T) {
+	defer resetRegistry()
+
+	log, err := RegisterPackage("testpkg.one")
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	_, err = RegisterPackage("testpkg.one")
+	assert.Error(t, err, "registering the same package twice should fail")
+
+	levels := PackageLevels()
+	assert.Contains(t, levels, "testpkg.one")
+	assert.Equal(t, InfoLevel, levels["testpkg.one"])
+}
+
+func TestSetPackageLevel(t *testing.T) {
+	defer resetRegistry()
+
+	_, err := RegisterPackage("testpkg.two")
+	require.NoError(t, err)
+
+	require.NoError(t, SetPackageLevel("testpkg.two", DebugLevel))
+	assert.Equal(t, DebugLevel, PackageLevels()["testpkg.two"])
+
+	assert.Error(t, SetPackageLevel("testpkg.unknown", DebugLevel))
+}
+
+func TestSetAllLevels(t *testing.T) {
+	defer resetRegistry()
+
+	names := []string{"testpkg.three.a", "testpkg.three.b", "testpkg.three.c"}
+	for _, name := range names {
+		_, err := RegisterPackage(name)
+		require.NoError(t, err)
+	}
+
+	SetAllLevels(ErrorLevel)
+
+	for _, lvl := range PackageLevels() {
+		assert.Equal(t, ErrorLevel, lvl)
+	}
+}
+
+func TestRegisterPackage_EmptyNameAutoDetectsCallerPackage(t *testing.T) {
+	defer resetRegistry()
+
+	log, err := registerPackageFromHelper()
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	assert.Contains(t, PackageLevels(), "zap")
+}
+
+// registerPackageFromHelper exists so TestRegisterPackage_EmptyNameAutoDetectsCallerPackage
+// can exercise callerPackage's stack-walking from a call site other than the
+// test function itself.
+func registerPackageFromHelper() (*ContextualLogger, error) {
+	return RegisterPackage("")
+}
+
+func TestLevelGatedCore_GatesEmittedLogs(t *testing.T) {
+	inner, logs := observer.New(DebugLevel)
+	atom := NewAtomicLevel()
+	atom.SetLevel(InfoLevel)
+	log := &ContextualLogger{base: New(levelGatedCore{Core: inner, level: atom})}
+
+	log.Debug(context.Background(), "gated out")
+	assert.Equal(t, 0, logs.Len())
+
+	log.Info(context.Background(), "allowed")
+	require.Equal(t, 1, logs.Len())
+
+	atom.SetLevel(DebugLevel)
+	log.Debug(context.Background(), "now allowed")
+	assert.Equal(t, 2, logs.Len())
+}
+
+func TestLevelGatedCore_WithPreservesGate(t *testing.T) {
+	inner, logs := observer.New(DebugLevel)
+	atom := NewAtomicLevel()
+	atom.SetLevel(InfoLevel)
+	log := &ContextualLogger{base: New(levelGatedCore{Core: inner, level: atom})}
+
+	// With (as called by ContextualLogger.With, or directly on a *Logger)
+	// must return a logger whose core is still gated by atom -- not the raw
+	// inner core with the gate dropped.
+	child := log.With(String("request_id", "abc"))
+
+	child.Debug(context.Background(), "still gated after With")
+	assert.Equal(t, 0, logs.Len())
+
+	atom.SetLevel(DebugLevel)
+	child.Debug(context.Background(), "now allowed after With")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "now allowed after With", logs.All()[0].Message)
+}
+
+func TestSetPackageLevelConcurrent(t *testing.T) {
+	defer resetRegistry()
+
+	const n = 50
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("testpkg.concurrent.%d", i)
+		_, err := RegisterPackage(names[i])
+		require.NoError(t, err)
+	}
+
+	levelCycle := []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				lvl := levelCycle[i%len(levelCycle)]
+				assert.NoError(t, SetPackageLevel(name, lvl))
+			}
+		}(name)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = PackageLevels()
+		}
+	}()
+	wg.Wait()
+
+	levels := PackageLevels()
+	assert.Len(t, levels, n)
+	for _, name := range names {
+		assert.Contains(t, levelCycle, levels[name])
+	}
+}
+
+// resetRegistry clears package-registry state between tests, since
+// RegisterPackage refuses to register the same name twice.
+func resetRegistry() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	packageLoggers = make(map[string]*ContextualLogger)
+	packageLevels = make(map[string]AtomicLevel)
+}